@@ -0,0 +1,269 @@
+package lrfu
+
+import "container/list"
+
+type arcEntry struct {
+	key   Key
+	value interface{}
+}
+
+// ARC implements the Adaptive Replacement Cache policy. It tracks a
+// recency list T1 and a frequency list T2, each with its own ghost list of
+// evicted keys (B1, B2), and adapts the target size p of T1 based on which
+// ghost list is hit, balancing between recency and frequency without
+// needing a tunable parameter.
+type ARC struct {
+	maxEntries int
+	p          int
+	OnEvicted  func(key Key, value interface{})
+
+	t1, t2, b1, b2     *list.List
+	t1m, t2m, b1m, b2m map[interface{}]*list.Element
+}
+
+// NewARC creates an ARC cache holding at most maxEntries entries.
+func NewARC(maxEntries int) *ARC {
+	return &ARC{
+		maxEntries: maxEntries,
+		t1:         list.New(),
+		t2:         list.New(),
+		b1:         list.New(),
+		b2:         list.New(),
+		t1m:        make(map[interface{}]*list.Element),
+		t2m:        make(map[interface{}]*list.Element),
+		b1m:        make(map[interface{}]*list.Element),
+		b2m:        make(map[interface{}]*list.Element),
+	}
+}
+
+// Set inserts or updates key with value.
+func (a *ARC) Set(key Key, value interface{}) {
+	if ele, hit := a.t1m[key]; hit {
+		ele.Value.(*arcEntry).value = value
+		a.promote(ele, a.t1, a.t1m)
+		return
+	}
+
+	if ele, hit := a.t2m[key]; hit {
+		ele.Value.(*arcEntry).value = value
+		a.t2.MoveToFront(ele)
+		return
+	}
+
+	if ele, hit := a.b1m[key]; hit {
+		a.adapt(+1)
+		a.replace(key)
+		a.b1.Remove(ele)
+		delete(a.b1m, key)
+
+		ne := a.t2.PushFront(&arcEntry{key: key, value: value})
+		a.t2m[key] = ne
+		return
+	}
+
+	if ele, hit := a.b2m[key]; hit {
+		a.adapt(-1)
+		a.replace(key)
+		a.b2.Remove(ele)
+		delete(a.b2m, key)
+
+		ne := a.t2.PushFront(&arcEntry{key: key, value: value})
+		a.t2m[key] = ne
+		return
+	}
+
+	if a.t1.Len()+a.b1.Len() == a.maxEntries {
+		if a.t1.Len() < a.maxEntries {
+			a.evictGhost(a.b1, a.b1m)
+			a.replace(key)
+		} else {
+			a.evictFrom(a.t1, a.t1m)
+		}
+	} else if a.t1.Len()+a.t2.Len()+a.b1.Len()+a.b2.Len() >= a.maxEntries {
+		if a.t1.Len()+a.t2.Len()+a.b1.Len()+a.b2.Len() == 2*a.maxEntries {
+			a.evictGhost(a.b2, a.b2m)
+		}
+		a.replace(key)
+	}
+
+	ne := a.t1.PushFront(&arcEntry{key: key, value: value})
+	a.t1m[key] = ne
+}
+
+// Get looks up key, promoting it from T1 to T2 on a second access.
+func (a *ARC) Get(key Key) (value interface{}, ok bool) {
+	if ele, hit := a.t1m[key]; hit {
+		v := ele.Value.(*arcEntry)
+		a.promote(ele, a.t1, a.t1m)
+		return v.value, true
+	}
+
+	if ele, hit := a.t2m[key]; hit {
+		a.t2.MoveToFront(ele)
+		return ele.Value.(*arcEntry).value, true
+	}
+
+	return
+}
+
+// Peek returns the value associated with key without promoting it.
+func (a *ARC) Peek(key Key) (value interface{}, ok bool) {
+	if ele, hit := a.t1m[key]; hit {
+		return ele.Value.(*arcEntry).value, true
+	}
+	if ele, hit := a.t2m[key]; hit {
+		return ele.Value.(*arcEntry).value, true
+	}
+	return
+}
+
+// Remove removes key from whichever of T1, T2, B1, or B2 it is currently
+// in, if any.
+func (a *ARC) Remove(key Key) (ok bool) {
+	if ele, hit := a.t1m[key]; hit {
+		a.t1.Remove(ele)
+		delete(a.t1m, key)
+		return true
+	}
+	if ele, hit := a.t2m[key]; hit {
+		a.t2.Remove(ele)
+		delete(a.t2m, key)
+		return true
+	}
+	if ele, hit := a.b1m[key]; hit {
+		a.b1.Remove(ele)
+		delete(a.b1m, key)
+		return true
+	}
+	if ele, hit := a.b2m[key]; hit {
+		a.b2.Remove(ele)
+		delete(a.b2m, key)
+		return true
+	}
+	return false
+}
+
+// Len returns the number of live (non-ghost) entries.
+func (a *ARC) Len() int {
+	return a.t1.Len() + a.t2.Len()
+}
+
+// Clear removes all entries, live and ghost.
+func (a *ARC) Clear() {
+	if a.OnEvicted != nil {
+		for _, e := range a.t1m {
+			kv := e.Value.(*arcEntry)
+			a.OnEvicted(kv.key, kv.value)
+		}
+		for _, e := range a.t2m {
+			kv := e.Value.(*arcEntry)
+			a.OnEvicted(kv.key, kv.value)
+		}
+	}
+
+	a.p = 0
+	a.t1, a.t2, a.b1, a.b2 = list.New(), list.New(), list.New(), list.New()
+	a.t1m = make(map[interface{}]*list.Element)
+	a.t2m = make(map[interface{}]*list.Element)
+	a.b1m = make(map[interface{}]*list.Element)
+	a.b2m = make(map[interface{}]*list.Element)
+}
+
+// SetOnEvicted sets OnEvicted, the callback fired whenever an entry is
+// evicted from T1 or T2.
+func (a *ARC) SetOnEvicted(fn func(key Key, value interface{})) {
+	a.OnEvicted = fn
+}
+
+func (a *ARC) promote(ele *list.Element, src *list.List, srcMap map[interface{}]*list.Element) {
+	kv := ele.Value.(*arcEntry)
+	src.Remove(ele)
+	delete(srcMap, kv.key)
+
+	ne := a.t2.PushFront(kv)
+	a.t2m[kv.key] = ne
+}
+
+func (a *ARC) adapt(direction int) {
+	if direction > 0 {
+		d := 1
+		if a.b1.Len() > 0 && a.b2.Len() > 0 {
+			d = maxInt(a.b2.Len()/a.b1.Len(), 1)
+		}
+		a.p = minInt(a.p+d, a.maxEntries)
+		return
+	}
+
+	d := 1
+	if a.b1.Len() > 0 && a.b2.Len() > 0 {
+		d = maxInt(a.b1.Len()/a.b2.Len(), 1)
+	}
+	a.p = maxInt(a.p-d, 0)
+}
+
+// replace evicts one entry from T1 or T2 into its ghost list, preferring T1
+// once it has grown past the adaptive target size p.
+func (a *ARC) replace(key Key) {
+	if a.t1.Len() > 0 && (a.t1.Len() > a.p || (a.t1.Len() == a.p && a.b2m[key] != nil)) {
+		a.moveToGhost(a.t1, a.t1m, a.b1, a.b1m)
+		return
+	}
+	a.moveToGhost(a.t2, a.t2m, a.b2, a.b2m)
+}
+
+func (a *ARC) moveToGhost(src *list.List, srcMap map[interface{}]*list.Element, ghost *list.List, ghostMap map[interface{}]*list.Element) {
+	ele := src.Back()
+	if ele == nil {
+		return
+	}
+
+	kv := ele.Value.(*arcEntry)
+	src.Remove(ele)
+	delete(srcMap, kv.key)
+
+	if a.OnEvicted != nil {
+		a.OnEvicted(kv.key, kv.value)
+	}
+
+	ge := ghost.PushFront(&arcEntry{key: kv.key})
+	ghostMap[kv.key] = ge
+}
+
+func (a *ARC) evictFrom(src *list.List, srcMap map[interface{}]*list.Element) {
+	ele := src.Back()
+	if ele == nil {
+		return
+	}
+
+	kv := ele.Value.(*arcEntry)
+	src.Remove(ele)
+	delete(srcMap, kv.key)
+
+	if a.OnEvicted != nil {
+		a.OnEvicted(kv.key, kv.value)
+	}
+}
+
+func (a *ARC) evictGhost(ghost *list.List, ghostMap map[interface{}]*list.Element) {
+	ele := ghost.Back()
+	if ele == nil {
+		return
+	}
+
+	ghost.Remove(ele)
+	delete(ghostMap, ele.Value.(*arcEntry).key)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+package lrfu
+
+import "testing"
+
+func TestARCSetGetRemove(t *testing.T) {
+	a := NewARC(4)
+
+	a.Set("a", 1)
+	a.Set("b", 2)
+
+	if v, ok := a.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, ok)
+	}
+
+	if !a.Remove("b") {
+		t.Fatal("Remove(b) = false; want true")
+	}
+	if _, ok := a.Get("b"); ok {
+		t.Fatal("Get(b) after Remove = true; want false")
+	}
+}
+
+func TestARCPromotesToT2OnSecondAccess(t *testing.T) {
+	a := NewARC(4)
+	a.Set("a", 1)
+
+	if a.t2.Len() != 0 || a.t1.Len() != 1 {
+		t.Fatalf("after one Set: t1=%d t2=%d, want t1=1 t2=0", a.t1.Len(), a.t2.Len())
+	}
+
+	a.Get("a")
+
+	if a.t2.Len() != 1 || a.t1.Len() != 0 {
+		t.Fatalf("after Get: t1=%d t2=%d, want t1=0 t2=1", a.t1.Len(), a.t2.Len())
+	}
+}
+
+func TestARCEvictsWhenFull(t *testing.T) {
+	a := NewARC(2)
+	var evicted []Key
+	a.SetOnEvicted(func(k Key, v interface{}) { evicted = append(evicted, k) })
+
+	a.Set("a", 1)
+	a.Set("b", 2)
+	a.Set("c", 3)
+
+	if a.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", a.Len())
+	}
+	if len(evicted) != 1 {
+		t.Fatalf("len(evicted) = %d, want 1", len(evicted))
+	}
+}
+
+func TestARCClear(t *testing.T) {
+	a := NewARC(4)
+	a.Set("a", 1)
+	a.Set("b", 2)
+
+	a.Clear()
+
+	if a.Len() != 0 {
+		t.Fatalf("Len() after Clear = %d, want 0", a.Len())
+	}
+	if _, ok := a.Get("a"); ok {
+		t.Fatal("Get(a) after Clear = true; want false")
+	}
+}
@@ -0,0 +1,146 @@
+package lrfu
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache wraps an LRFU with a sync.RWMutex so callers don't need to
+// replicate their own locking around it, the way callers of the bare LRFU
+// currently have to.
+type Cache struct {
+	mu  sync.RWMutex
+	lru *LRFU
+
+	janitorStop chan struct{}
+}
+
+// NewCache creates a thread-safe Cache backed by an LRFU with the given
+// capacity and recency/frequency weight.
+func NewCache(maxEntries int, lambda float64) *Cache {
+	return &Cache{lru: NewLRFU(maxEntries, lambda)}
+}
+
+// Set inserts or updates key with value.
+func (c *Cache) Set(key Key, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Set(key, value)
+}
+
+// Get looks up key, also updating its recency/frequency weight.
+func (c *Cache) Get(key Key) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Get(key)
+}
+
+// Remove removes key from the cache, if present.
+func (c *Cache) Remove(key Key) (ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Remove(key)
+}
+
+// Len returns the number of entries currently cached.
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lru.Len()
+}
+
+// Clear removes all entries from the cache.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Clear()
+}
+
+// OnEvicted registers fn to be called whenever an entry is evicted. It must
+// be called before the cache is used concurrently.
+func (c *Cache) OnEvicted(fn func(key Key, value interface{})) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.OnEvicted = fn
+}
+
+// OnExpired registers fn to be called whenever an entry is removed because
+// it reached its TTL, instead of OnEvicted. It must be called before the
+// cache is used concurrently.
+func (c *Cache) OnExpired(fn func(key Key, value interface{})) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.OnExpired = fn
+}
+
+// Peek returns the value associated with key without updating its CRF or
+// recency.
+func (c *Cache) Peek(key Key) (value interface{}, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lru.Peek(key)
+}
+
+// SetWithTTL inserts or updates key with value, expiring it ttl from now.
+func (c *Cache) SetWithTTL(key Key, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.SetWithTTL(key, value, ttl)
+}
+
+// SetWithDeadline inserts or updates key with value, expiring it at
+// deadline.
+func (c *Cache) SetWithDeadline(key Key, value interface{}, deadline time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.SetWithDeadline(key, value, deadline)
+}
+
+// TTL returns the remaining time-to-live for key, without updating its CRF
+// or recency.
+func (c *Cache) TTL(key Key) (ttl time.Duration, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lru.TTL(key)
+}
+
+// StartJanitor starts a background goroutine that periodically reaps
+// expired entries under Cache's own lock, so unlike LRFU.StartJanitor it is
+// safe to run alongside concurrent Set/Get. A prior janitor, if running, is
+// stopped first.
+func (c *Cache) StartJanitor(interval time.Duration) {
+	c.StopJanitor()
+
+	stop := make(chan struct{})
+	c.mu.Lock()
+	c.janitorStop = stop
+	c.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.mu.Lock()
+				c.lru.reapExpired()
+				c.mu.Unlock()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopJanitor stops the background janitor goroutine started by
+// StartJanitor, if any.
+func (c *Cache) StopJanitor() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.janitorStop != nil {
+		close(c.janitorStop)
+		c.janitorStop = nil
+	}
+}
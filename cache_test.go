@@ -0,0 +1,55 @@
+package lrfu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheSetGetRemove(t *testing.T) {
+	c := NewCache(10, 0.01)
+
+	c.Set("a", 1)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, ok)
+	}
+
+	if !c.Remove("a") {
+		t.Fatal("Remove(a) = false; want true")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Get(a) after Remove = true; want false")
+	}
+}
+
+func TestCacheTTLExpiresUnderLock(t *testing.T) {
+	c := NewCache(10, 0.01)
+	c.SetWithTTL("a", 1, 20*time.Millisecond)
+
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected hit before expiry")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected miss after expiry")
+	}
+}
+
+func TestCacheJanitorReapsExpired(t *testing.T) {
+	c := NewCache(10, 0.01)
+	c.SetWithTTL("a", 1, 10*time.Millisecond)
+
+	c.StartJanitor(5 * time.Millisecond)
+	defer c.StopJanitor()
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if c.Len() == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatal("expected janitor to reap the expired entry")
+}
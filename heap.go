@@ -0,0 +1,45 @@
+package lrfu
+
+// crfHeap is a container/heap.Interface over *entry, ordered by each
+// entry's current CRF (lowest first), so RemoveElement can pop the true
+// minimum-CRF victim in O(log n) instead of taking the list tail, as the
+// previous single-pointer "smallest" tracking did.
+//
+// Entries are compared live via the owning LRFU's getCRF, which decays
+// lastCRF relative to the current global reference count. Because that
+// decay is a shared exponential factor across all entries, the relative
+// order between any two entries never changes except when one of them is
+// actually touched — so heap.Fix only needs to run on the touched entry
+// after a Set/Get, giving the paper's lazy re-heapification.
+type crfHeap struct {
+	entries []*entry
+	lru     *LRFU
+}
+
+func (h crfHeap) Len() int { return len(h.entries) }
+
+func (h crfHeap) Less(i, j int) bool {
+	return h.lru.getCRF(h.entries[i]) < h.lru.getCRF(h.entries[j])
+}
+
+func (h crfHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index = i
+	h.entries[j].index = j
+}
+
+func (h *crfHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *crfHeap) Pop() interface{} {
+	old := h.entries
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	h.entries = old[:n-1]
+	return e
+}
@@ -3,8 +3,9 @@
 package lrfu
 
 import (
-	"container/list"
+	"container/heap"
 	"math"
+	"time"
 )
 
 // Ensure that it's a comparable type. See http://golang.org/ref/spec#Comparison_operators
@@ -15,46 +16,88 @@ type entry struct {
 	value         interface{}
 	lastReference uint
 	lastCRF       float64
+	expiresAt     time.Time
+	size          int64
+	index         int // position in the owning LRFU's heap
 }
 
+// Sizer computes the size in bytes that value occupies for a given key, for
+// use with NewLRFUWithSize's byte-budgeted capacity mode.
+type Sizer func(key Key, value interface{}) int64
+
 // This is not thread-safe, which means it will depend on the parent implementation to do the locking mechanism.
 type LRFU struct {
 	maxEntries int
 	lambda     float64
 	OnEvicted  func(key Key, value interface{})
-	ll         *list.List
-	cache      map[interface{}]*list.Element
-	count      uint
-	smallest   *list.Element
+	// OnExpired, if set, is called instead of OnEvicted when an entry is
+	// removed because it reached its TTL, so callers can tell capacity
+	// evictions apart from expirations.
+	OnExpired func(key Key, value interface{})
+	cache     map[interface{}]*entry
+	heap      crfHeap
+	count     uint
+
+	janitorStop chan struct{}
+
+	maxBytes int64
+	bytes    int64
+	sizeOf   Sizer
 }
 
 func NewLRFU(maxEntries int, lambda float64) *LRFU {
-	return &LRFU{
+	lru := &LRFU{
 		maxEntries: maxEntries,
 		lambda:     lambda,
-		ll:         list.New(),
-		cache:      make(map[interface{}]*list.Element),
+		cache:      make(map[interface{}]*entry),
 		count:      0,
 	}
+	lru.heap = crfHeap{lru: lru}
+	return lru
+}
+
+// NewLRFUWithSize creates an LRFU that evicts based on a byte budget rather
+// than an entry count: maxEntries is left unbounded, and after each Set the
+// minimum-CRF entry is evicted until the total size reported by sizeOf is
+// at most maxBytes. This suits caches of variable-sized values such as
+// blobs or DNS responses.
+func NewLRFUWithSize(maxBytes int64, lambda float64, sizeOf Sizer) *LRFU {
+	lru := NewLRFU(0, lambda)
+	lru.maxBytes = maxBytes
+	lru.sizeOf = sizeOf
+	return lru
+}
+
+// Bytes returns the total size of all cached entries, as reported by the
+// Sizer passed to NewLRFUWithSize. It is always 0 for an LRFU created with
+// NewLRFU.
+func (lru *LRFU) Bytes() int64 {
+	return lru.bytes
+}
+
+// Cap returns the byte budget passed to NewLRFUWithSize, or 0 if the LRFU
+// was created with NewLRFU.
+func (lru *LRFU) Cap() int64 {
+	return lru.maxBytes
 }
 
 func (lru *LRFU) Set(key Key, value interface{}) {
 	if lru.cache == nil {
-		lru.cache = make(map[interface{}]*list.Element)
-		lru.ll = list.New()
+		lru.cache = make(map[interface{}]*entry)
+		lru.heap = crfHeap{lru: lru}
 		lru.count = 0
-		lru.smallest = nil
 	}
 
 	lru.count++
 
-	if ele, ok := lru.cache[key]; ok {
-		lru.ll.MoveToFront(ele)
-		ele.Value.(*entry).lastCRF = lru.getWeight(0) + lru.getCRF(ele.Value.(*entry))
-		ele.Value.(*entry).lastReference = lru.count
-		ele.Value.(*entry).value = value
-		lru.restore(ele)
+	if en, ok := lru.cache[key]; ok {
+		en.lastCRF = lru.getWeight(0) + lru.getCRF(en)
+		en.lastReference = lru.count
+		en.value = value
+		lru.resize(en, value)
+		heap.Fix(&lru.heap, en.index)
 
+		lru.evictToFit()
 		return
 	}
 
@@ -64,12 +107,39 @@ func (lru *LRFU) Set(key Key, value interface{}) {
 		lastReference: lru.count,
 		lastCRF:       lru.getWeight(0),
 	}
+	lru.resize(e, value)
 
-	ele := lru.ll.PushFront(e)
-	lru.cache[key] = ele
-	lru.restore(ele)
+	lru.cache[key] = e
+	heap.Push(&lru.heap, e)
 
-	if lru.maxEntries != 0 && lru.ll.Len() > lru.maxEntries {
+	if lru.maxEntries != 0 && lru.heap.Len() > lru.maxEntries {
+		lru.RemoveElement()
+	}
+
+	lru.evictToFit()
+}
+
+// resize updates en's recorded size and the running byte total for the
+// delta, so a re-Set with a larger or smaller value recomputes correctly.
+func (lru *LRFU) resize(en *entry, value interface{}) {
+	if lru.sizeOf == nil {
+		return
+	}
+
+	lru.bytes -= en.size
+	en.size = lru.sizeOf(en.key, value)
+	lru.bytes += en.size
+}
+
+// evictToFit evicts the minimum-CRF entry repeatedly until the cache is
+// back within its byte budget. It is a no-op unless the LRFU was created
+// with NewLRFUWithSize.
+func (lru *LRFU) evictToFit() {
+	if lru.maxBytes == 0 {
+		return
+	}
+
+	for lru.bytes > lru.maxBytes && lru.heap.Len() > 0 {
 		lru.RemoveElement()
 	}
 }
@@ -81,72 +151,182 @@ func (lru *LRFU) Get(key Key) (value interface{}, ok bool) {
 
 	lru.count++
 
-	if ele, hit := lru.cache[key]; hit {
-		lru.ll.MoveToFront(ele)
-		ele.Value.(*entry).lastCRF = lru.getWeight(0) + lru.getCRF(ele.Value.(*entry))
-		ele.Value.(*entry).lastReference = lru.count
-		lru.restore(ele)
-		return ele.Value.(*entry).value, true
+	if en, hit := lru.cache[key]; hit {
+		if lru.expired(en) {
+			lru.expireElement(en)
+			return
+		}
+
+		en.lastCRF = lru.getWeight(0) + lru.getCRF(en)
+		en.lastReference = lru.count
+		heap.Fix(&lru.heap, en.index)
+		return en.value, true
 	}
 
 	return
 }
 
-func (lru *LRFU) restore(ele *list.Element) {
-	if lru.smallest == nil {
-		lru.smallest = ele
+// SetWithTTL inserts or updates key with value, expiring it ttl from now.
+// A non-positive ttl means the entry never expires.
+func (lru *LRFU) SetWithTTL(key Key, value interface{}, ttl time.Duration) {
+	var deadline time.Time
+	if ttl > 0 {
+		deadline = time.Now().Add(ttl)
+	}
+	lru.setWithDeadline(key, value, deadline)
+}
+
+// SetWithDeadline inserts or updates key with value, expiring it at
+// deadline. A zero deadline means the entry never expires.
+func (lru *LRFU) SetWithDeadline(key Key, value interface{}, deadline time.Time) {
+	lru.setWithDeadline(key, value, deadline)
+}
+
+func (lru *LRFU) setWithDeadline(key Key, value interface{}, deadline time.Time) {
+	lru.Set(key, value)
+
+	if en, ok := lru.cache[key]; ok {
+		en.expiresAt = deadline
+	}
+}
+
+// TTL returns the remaining time-to-live for key, without touching its CRF
+// or recency, so external observability code doesn't perturb the
+// replacement decision. It reports ok=false if key is absent, already
+// expired, or has no expiration set.
+func (lru *LRFU) TTL(key Key) (ttl time.Duration, ok bool) {
+	if lru.cache == nil {
 		return
 	}
 
-	fe := lru.ll.Front()
+	en, hit := lru.cache[key]
+	if !hit {
+		return
+	}
 
-	en := ele.Value.(*entry)
-	smallest := lru.smallest.Value.(*entry)
-	if fe.Value.(*entry).key != en.key {
-		if lru.getCRF(en) > lru.getCRF(smallest) {
-			*en, *smallest = *smallest, *en
-			lru.cache[en.key] = ele
-			lru.cache[smallest.key] = lru.smallest
-			lru.restore(lru.smallest)
-			return
+	if en.expiresAt.IsZero() || lru.expired(en) {
+		return
+	}
+
+	return en.expiresAt.Sub(time.Now()), true
+}
+
+func (lru *LRFU) expired(en *entry) bool {
+	return !en.expiresAt.IsZero() && !en.expiresAt.After(time.Now())
+}
+
+// StartJanitor starts a background goroutine that reaps expired entries
+// every interval. LRFU itself is not safe for concurrent use, so this must
+// not run alongside concurrent Set/Get; use Cache.StartJanitor instead if
+// you need both. A prior janitor, if running, is stopped first.
+func (lru *LRFU) StartJanitor(interval time.Duration) {
+	lru.StopJanitor()
+
+	stop := make(chan struct{})
+	lru.janitorStop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				lru.reapExpired()
+			case <-stop:
+				return
+			}
 		}
+	}()
+}
 
-		lru.smallest = ele
+// StopJanitor stops the background janitor goroutine started by
+// StartJanitor, if any.
+func (lru *LRFU) StopJanitor() {
+	if lru.janitorStop != nil {
+		close(lru.janitorStop)
+		lru.janitorStop = nil
 	}
-	return
 }
 
-func (lru *LRFU) getCRF(en *entry) float64 {
-	return lru.getWeight(lru.count-en.lastReference) * en.lastCRF
+func (lru *LRFU) reapExpired() {
+	if lru.cache == nil {
+		return
+	}
+
+	for _, en := range lru.cache {
+		if lru.expired(en) {
+			lru.expireElement(en)
+		}
+	}
 }
 
-func (lru *LRFU) RemoveElement() {
+// Peek returns the value associated with key without updating its CRF or
+// recency, so it can be used from observability code without perturbing
+// the replacement decision.
+func (lru *LRFU) Peek(key Key) (value interface{}, ok bool) {
 	if lru.cache == nil {
 		return
 	}
-	ele := lru.ll.Back()
 
-	if ele != nil {
-		lru.removeElement(ele)
+	if en, hit := lru.cache[key]; hit {
+		if lru.expired(en) {
+			return
+		}
+		return en.value, true
 	}
+
+	return
 }
 
-func (lru *LRFU) getWeight(v uint) float64 {
-	return math.Pow((1 / 2), lru.lambda*float64(v))
+// SetOnEvicted sets OnEvicted. It exists so LRFU satisfies Policy, which
+// has no way to expose a public field.
+func (lru *LRFU) SetOnEvicted(fn func(key Key, value interface{})) {
+	lru.OnEvicted = fn
 }
 
-func (lru *LRFU) removeElement(e *list.Element) {
-	if lru.smallest.Value.(*entry).key == e.Value.(*entry).key {
-		lru.smallest = nil
+func (lru *LRFU) getCRF(en *entry) float64 {
+	return lru.getWeight(lru.count-en.lastReference) * en.lastCRF
+}
+
+// RemoveElement evicts the entry with the lowest current CRF, as required
+// by the LRFU replacement policy.
+func (lru *LRFU) RemoveElement() {
+	if lru.cache == nil || lru.heap.Len() == 0 {
+		return
 	}
 
-	lru.ll.Remove(e)
-	kv := e.Value.(*entry)
+	en := heap.Pop(&lru.heap).(*entry)
+	lru.removeEntry(en)
+}
+
+func (lru *LRFU) getWeight(v uint) float64 {
+	return math.Pow(0.5, lru.lambda*float64(v))
+}
 
-	delete(lru.cache, kv.key)
+// removeEntry drops en from the cache. The caller is responsible for
+// removing it from the heap first, e.g. via heap.Pop or heap.Remove.
+func (lru *LRFU) removeEntry(en *entry) {
+	delete(lru.cache, en.key)
+	lru.bytes -= en.size
 
 	if lru.OnEvicted != nil {
-		lru.OnEvicted(kv.key, kv.value)
+		lru.OnEvicted(en.key, en.value)
+	}
+}
+
+// expireElement removes en because it reached its TTL, firing OnExpired
+// instead of OnEvicted so callers can tell the two apart. If OnExpired is
+// nil, OnEvicted is fired.
+func (lru *LRFU) expireElement(en *entry) {
+	heap.Remove(&lru.heap, en.index)
+	delete(lru.cache, en.key)
+	lru.bytes -= en.size
+
+	if lru.OnExpired != nil {
+		lru.OnExpired(en.key, en.value)
+	} else if lru.OnEvicted != nil {
+		lru.OnEvicted(en.key, en.value)
 	}
 }
 
@@ -154,7 +334,7 @@ func (lru *LRFU) Len() int {
 	if lru.cache == nil {
 		return 0
 	}
-	return lru.ll.Len()
+	return lru.heap.Len()
 }
 
 func (lru *LRFU) Remove(key Key) (ok bool) {
@@ -162,24 +342,24 @@ func (lru *LRFU) Remove(key Key) (ok bool) {
 		return
 	}
 
-	if ele, hit := lru.cache[key]; hit {
-		lru.removeElement(ele)
+	if en, hit := lru.cache[key]; hit {
+		heap.Remove(&lru.heap, en.index)
+		lru.removeEntry(en)
 		return true
 	}
 
 	return false
-
 }
 
 func (lru *LRFU) Clear() {
 	if lru.OnEvicted != nil {
-		for _, e := range lru.cache {
-			kv := e.Value.(*entry)
-			lru.OnEvicted(kv.key, kv.value)
+		for _, en := range lru.cache {
+			lru.OnEvicted(en.key, en.value)
 		}
 	}
 
-	lru.ll = nil
 	lru.cache = nil
+	lru.heap = crfHeap{}
 	lru.count = 0
+	lru.bytes = 0
 }
@@ -0,0 +1,126 @@
+package lrfu
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// bruteForceMinCRF scans every live entry and returns the lowest current
+// CRF, the O(n) reference this package's min-heap is meant to replace.
+func bruteForceMinCRF(lru *LRFU) float64 {
+	min := math.MaxFloat64
+	for _, en := range lru.cache {
+		if crf := lru.getCRF(en); crf < min {
+			min = crf
+		}
+	}
+	return min
+}
+
+// TestRemoveElementPicksMinimumCRF drives a random mix of Set/Get over a
+// small keyspace and checks, before every eviction, that the heap's root
+// agrees with a brute-force scan of the true minimum CRF.
+func TestRemoveElementPicksMinimumCRF(t *testing.T) {
+	lru := NewLRFU(0, 0.01)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		k := r.Intn(50)
+		if r.Intn(3) == 0 {
+			lru.Get(k)
+		} else {
+			lru.Set(k, k)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		want := bruteForceMinCRF(lru)
+		got := lru.getCRF(lru.heap.entries[0])
+		if got != want {
+			t.Fatalf("iteration %d: heap root CRF = %v, want brute-force min %v", i, got, want)
+		}
+		lru.RemoveElement()
+	}
+}
+
+// TestGetWeightDecays guards against getWeight regressing into the
+// integer-division bug where (1/2) evaluates to 0, collapsing every
+// entry's CRF to the same value regardless of recency.
+func TestGetWeightDecays(t *testing.T) {
+	lru := NewLRFU(0, 1.0)
+
+	w0 := lru.getWeight(0)
+	w1 := lru.getWeight(1)
+	w5 := lru.getWeight(5)
+
+	if w0 != 1 {
+		t.Fatalf("getWeight(0) = %v, want 1", w0)
+	}
+	if w1 <= 0 || w1 >= w0 {
+		t.Fatalf("getWeight(1) = %v, want a value strictly between 0 and %v", w1, w0)
+	}
+	if w5 <= 0 || w5 >= w1 {
+		t.Fatalf("getWeight(5) = %v, want a value strictly between 0 and %v", w5, w1)
+	}
+}
+
+// TestFrequentlyUsedEntrySurvives is a regression test for the bug
+// TestGetWeightDecays targets at the getWeight level: a key that is
+// clearly the most frequently and recently used must not be evicted ahead
+// of keys touched only once.
+func TestFrequentlyUsedEntrySurvives(t *testing.T) {
+	lru := NewLRFU(0, 0.5)
+
+	lru.Set("hot", 1)
+	lru.Get("hot")
+	lru.Get("hot")
+	lru.Get("hot")
+
+	lru.Set("cold1", 1)
+	lru.Set("cold2", 1)
+	lru.RemoveElement()
+
+	if _, ok := lru.Get("hot"); !ok {
+		t.Fatal("expected \"hot\" to survive eviction over cold, once-touched keys")
+	}
+}
+
+func TestLRFUWithSizeEvictsToFit(t *testing.T) {
+	sizeOf := func(key Key, value interface{}) int64 { return value.(int64) }
+	lru := NewLRFUWithSize(10, 0.01, sizeOf)
+
+	lru.Set("a", int64(4))
+	lru.Set("b", int64(4))
+	lru.Set("c", int64(4)) // total would be 12 > 10, so something must be evicted
+
+	if got := lru.Bytes(); got > 10 {
+		t.Fatalf("Bytes() = %d, want at most 10", got)
+	}
+	if got := lru.Cap(); got != 10 {
+		t.Fatalf("Cap() = %d, want 10", got)
+	}
+}
+
+// TestLRFUWithSizeRecomputesDeltaOnUpdate checks that re-Setting an
+// existing key with a different-sized value adjusts Bytes() by the delta,
+// not by double-counting or dropping the old size.
+func TestLRFUWithSizeRecomputesDeltaOnUpdate(t *testing.T) {
+	sizeOf := func(key Key, value interface{}) int64 { return value.(int64) }
+	lru := NewLRFUWithSize(100, 0.01, sizeOf)
+
+	lru.Set("a", int64(4))
+	if got := lru.Bytes(); got != 4 {
+		t.Fatalf("Bytes() after first Set = %d, want 4", got)
+	}
+
+	lru.Set("a", int64(10))
+	if got := lru.Bytes(); got != 10 {
+		t.Fatalf("Bytes() after growing update = %d, want 10", got)
+	}
+
+	lru.Set("a", int64(2))
+	if got := lru.Bytes(); got != 2 {
+		t.Fatalf("Bytes() after shrinking update = %d, want 2", got)
+	}
+}
@@ -0,0 +1,21 @@
+package lrfu
+
+// Policy is the common interface satisfied by every eviction policy in this
+// package (LRFU, ARC, TwoQueue, Sieve), so callers can pick whichever fits their
+// workload without depending on multiple cache libraries.
+type Policy interface {
+	Set(key Key, value interface{})
+	Get(key Key) (value interface{}, ok bool)
+	Peek(key Key) (value interface{}, ok bool)
+	Remove(key Key) (ok bool)
+	Len() int
+	Clear()
+	SetOnEvicted(fn func(key Key, value interface{}))
+}
+
+var (
+	_ Policy = (*LRFU)(nil)
+	_ Policy = (*ARC)(nil)
+	_ Policy = (*TwoQueue)(nil)
+	_ Policy = (*Sieve)(nil)
+)
@@ -0,0 +1,154 @@
+package lrfu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+// HashFunc computes a shard key for key. It only needs to distribute keys
+// roughly evenly; it does not need to be cryptographically strong.
+type HashFunc func(key Key) uint32
+
+// ShardedCache spreads keys across a fixed, power-of-two number of
+// independently locked Caches to reduce lock contention under concurrent
+// access compared to a single Cache.
+type ShardedCache struct {
+	shards []*Cache
+	mask   uint32
+	hash   HashFunc
+}
+
+// NewShardedCache creates a ShardedCache with numShards shards (rounded up
+// to the next power of two), splitting maxEntries across them so the
+// shards' capacities sum to maxEntries: maxEntries%numShards shards get
+// maxEntries/numShards+1 entries and the rest get maxEntries/numShards. The
+// one exception is maxEntries < numShards, where maxEntries/numShards would
+// floor to 0 (the unbounded LRFU capacity) for every shard; there, every
+// shard instead gets a floor of 1, so the total capacity is numShards
+// rather than maxEntries. A nil hash defaults to DefaultHash.
+func NewShardedCache(numShards int, maxEntries int, lambda float64, hash HashFunc) *ShardedCache {
+	if numShards < 1 {
+		numShards = 1
+	}
+	numShards = nextPowerOfTwo(numShards)
+
+	if hash == nil {
+		hash = DefaultHash
+	}
+
+	base, remainder := 0, 0
+	if maxEntries != 0 {
+		base = maxEntries / numShards
+		remainder = maxEntries % numShards
+		if base == 0 {
+			base = 1
+			remainder = 0
+		}
+	}
+
+	shards := make([]*Cache, numShards)
+	for i := range shards {
+		capacity := base
+		if i < remainder {
+			capacity++
+		}
+		shards[i] = NewCache(capacity, lambda)
+	}
+
+	return &ShardedCache{
+		shards: shards,
+		mask:   uint32(numShards - 1),
+		hash:   hash,
+	}
+}
+
+// Set inserts or updates key with value in its shard.
+func (s *ShardedCache) Set(key Key, value interface{}) {
+	s.shardFor(key).Set(key, value)
+}
+
+// Get looks up key in its shard, also updating its recency/frequency
+// weight.
+func (s *ShardedCache) Get(key Key) (value interface{}, ok bool) {
+	return s.shardFor(key).Get(key)
+}
+
+// Remove removes key from its shard, if present.
+func (s *ShardedCache) Remove(key Key) (ok bool) {
+	return s.shardFor(key).Remove(key)
+}
+
+// Len returns the total number of entries cached across all shards.
+func (s *ShardedCache) Len() int {
+	n := 0
+	for _, shard := range s.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Clear removes all entries from every shard.
+func (s *ShardedCache) Clear() {
+	for _, shard := range s.shards {
+		shard.Clear()
+	}
+}
+
+// OnEvicted registers fn on every shard to be called whenever that shard
+// evicts an entry. It must be called before the cache is used concurrently.
+func (s *ShardedCache) OnEvicted(fn func(key Key, value interface{})) {
+	for _, shard := range s.shards {
+		shard.OnEvicted(fn)
+	}
+}
+
+func (s *ShardedCache) shardFor(key Key) *Cache {
+	return s.shards[s.hash(key)&s.mask]
+}
+
+// DefaultHash hashes strings, []byte and the common integer key types with
+// FNV-1a, falling back to hashing the key's string representation for
+// anything else.
+func DefaultHash(key Key) uint32 {
+	h := fnv.New32a()
+
+	switch k := key.(type) {
+	case string:
+		h.Write([]byte(k))
+	case []byte:
+		h.Write(k)
+	case int:
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:])
+	case int64:
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:])
+	case uint64:
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], k)
+		h.Write(buf[:])
+	case int32:
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], uint32(k))
+		h.Write(buf[:])
+	case uint32:
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], k)
+		h.Write(buf[:])
+	default:
+		fmt.Fprintf(h, "%v", k)
+	}
+
+	return h.Sum32()
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,72 @@
+package lrfu
+
+import "testing"
+
+func TestShardedCacheSetGet(t *testing.T) {
+	s := NewShardedCache(4, 100, 0.01, nil)
+
+	for i := 0; i < 20; i++ {
+		s.Set(i, i*10)
+	}
+	for i := 0; i < 20; i++ {
+		v, ok := s.Get(i)
+		if !ok || v != i*10 {
+			t.Fatalf("Get(%d) = %v, %v; want %v, true", i, v, ok, i*10)
+		}
+	}
+	if got := s.Len(); got != 20 {
+		t.Fatalf("Len() = %d, want 20", got)
+	}
+}
+
+func TestShardedCacheRoundsNumShardsUpToPowerOfTwo(t *testing.T) {
+	s := NewShardedCache(3, 100, 0.01, nil)
+	if got := len(s.shards); got != 4 {
+		t.Fatalf("len(shards) = %d, want 4", got)
+	}
+}
+
+// TestShardedCacheSmallCapacityStaysBounded guards against a small
+// maxEntries silently rounding down to 0 (unbounded) capacity per shard
+// when it doesn't divide evenly across numShards. maxEntries < numShards is
+// the one case where the total can't match maxEntries exactly (each shard
+// needs at least 1 slot), so it is bounded by numShards instead.
+func TestShardedCacheSmallCapacityStaysBounded(t *testing.T) {
+	s := NewShardedCache(8, 3, 0.01, nil)
+
+	for i := 0; i < 1000; i++ {
+		s.Set(i, i)
+	}
+
+	if got := s.Len(); got > 8 {
+		t.Fatalf("Len() = %d, want at most 8 (1 per shard)", got)
+	}
+}
+
+// TestShardedCacheCapacityDistributesRemainderExactly checks that when
+// maxEntries is at least numShards, the per-shard capacities the remainder
+// is split across sum to exactly maxEntries rather than rounding every
+// shard's share up to the next multiple of numShards.
+func TestShardedCacheCapacityDistributesRemainderExactly(t *testing.T) {
+	s := NewShardedCache(4, 10, 0.01, nil)
+
+	for i := 0; i < 1000; i++ {
+		s.Set(i, i)
+	}
+
+	if got := s.Len(); got > 10 {
+		t.Fatalf("Len() = %d, want at most 10", got)
+	}
+}
+
+func TestShardedCacheClear(t *testing.T) {
+	s := NewShardedCache(4, 100, 0.01, nil)
+	s.Set("a", 1)
+	s.Set("b", 2)
+
+	s.Clear()
+
+	if got := s.Len(); got != 0 {
+		t.Fatalf("Len() after Clear = %d, want 0", got)
+	}
+}
@@ -0,0 +1,144 @@
+package lrfu
+
+import "container/list"
+
+type sieveEntry struct {
+	key     Key
+	value   interface{}
+	visited bool
+}
+
+// Sieve implements the SIEVE eviction policy: a single FIFO-ordered list
+// plus a visited bit per entry and a moving hand. Get only flips the
+// visited bit and never touches the list, making reads cheap; eviction
+// walks backwards from the hand, clearing visited bits until it finds an
+// entry to evict.
+type Sieve struct {
+	maxEntries int
+	OnEvicted  func(key Key, value interface{})
+
+	ll   *list.List
+	hand *list.Element
+	m    map[interface{}]*list.Element
+}
+
+// NewSieve creates a Sieve cache holding at most maxEntries entries.
+func NewSieve(maxEntries int) *Sieve {
+	return &Sieve{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		m:          make(map[interface{}]*list.Element),
+	}
+}
+
+// Set inserts or updates key with value. New entries are pushed to the
+// head of the list with visited=false.
+func (s *Sieve) Set(key Key, value interface{}) {
+	if ele, hit := s.m[key]; hit {
+		en := ele.Value.(*sieveEntry)
+		en.value = value
+		en.visited = true
+		return
+	}
+
+	if s.maxEntries != 0 && s.ll.Len() >= s.maxEntries {
+		s.evict()
+	}
+
+	ele := s.ll.PushFront(&sieveEntry{key: key, value: value})
+	s.m[key] = ele
+}
+
+// Get looks up key, setting its visited bit on a hit. It never moves the
+// entry within the list.
+func (s *Sieve) Get(key Key) (value interface{}, ok bool) {
+	if ele, hit := s.m[key]; hit {
+		ele.Value.(*sieveEntry).visited = true
+		return ele.Value.(*sieveEntry).value, true
+	}
+	return
+}
+
+// Peek returns the value associated with key without setting its visited
+// bit.
+func (s *Sieve) Peek(key Key) (value interface{}, ok bool) {
+	if ele, hit := s.m[key]; hit {
+		return ele.Value.(*sieveEntry).value, true
+	}
+	return
+}
+
+// Remove removes key and drops it from the sieve list, if present.
+func (s *Sieve) Remove(key Key) (ok bool) {
+	if ele, hit := s.m[key]; hit {
+		s.removeElement(ele)
+		return true
+	}
+	return false
+}
+
+// Len returns the number of entries currently cached.
+func (s *Sieve) Len() int {
+	return s.ll.Len()
+}
+
+// Clear removes all entries.
+func (s *Sieve) Clear() {
+	if s.OnEvicted != nil {
+		for _, e := range s.m {
+			kv := e.Value.(*sieveEntry)
+			s.OnEvicted(kv.key, kv.value)
+		}
+	}
+
+	s.ll = list.New()
+	s.hand = nil
+	s.m = make(map[interface{}]*list.Element)
+}
+
+// SetOnEvicted sets OnEvicted, the callback fired whenever an entry is
+// evicted from the sieve list.
+func (s *Sieve) SetOnEvicted(fn func(key Key, value interface{})) {
+	s.OnEvicted = fn
+}
+
+// evict walks backwards from the hand, clearing visited bits, until it
+// finds an unvisited entry to evict, leaving the hand at that entry's
+// predecessor.
+func (s *Sieve) evict() {
+	ele := s.hand
+	if ele == nil {
+		ele = s.ll.Back()
+	}
+
+	for ele != nil {
+		en := ele.Value.(*sieveEntry)
+		if !en.visited {
+			s.hand = ele.Prev()
+			s.removeElement(ele)
+			return
+		}
+
+		en.visited = false
+
+		prev := ele.Prev()
+		if prev == nil {
+			prev = s.ll.Back()
+		}
+		ele = prev
+	}
+}
+
+func (s *Sieve) removeElement(ele *list.Element) {
+	if s.hand == ele {
+		s.hand = ele.Prev()
+	}
+
+	kv := ele.Value.(*sieveEntry)
+	s.ll.Remove(ele)
+	delete(s.m, kv.key)
+
+	if s.OnEvicted != nil {
+		s.OnEvicted(kv.key, kv.value)
+	}
+}
@@ -0,0 +1,101 @@
+package lrfu
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// zipfianTrace generates n accesses over a keyspace keys wide, skewed
+// towards a small set of hot keys the way real cache workloads are.
+func zipfianTrace(n int, keyspace uint64, seed int64) []uint64 {
+	r := rand.New(rand.NewSource(seed))
+	z := rand.NewZipf(r, 1.1, 1, keyspace-1)
+
+	trace := make([]uint64, n)
+	for i := range trace {
+		trace[i] = z.Uint64()
+	}
+	return trace
+}
+
+// benchmarkPolicyZipfian replays a Zipfian trace through p, reporting both
+// the operation latency (via the benchmark's own timer) and the resulting
+// hit rate, so Sieve and LRFU can be compared on equal footing.
+func benchmarkPolicyZipfian(b *testing.B, p Policy) {
+	trace := zipfianTrace(b.N, 10000, 1)
+
+	var hits int
+	b.ResetTimer()
+	for _, k := range trace {
+		if _, ok := p.Get(k); ok {
+			hits++
+			continue
+		}
+		p.Set(k, k)
+	}
+	b.StopTimer()
+
+	if b.N > 0 {
+		b.ReportMetric(float64(hits)/float64(b.N)*100, "hit-rate%")
+	}
+}
+
+func BenchmarkSieveZipfian(b *testing.B) {
+	benchmarkPolicyZipfian(b, NewSieve(1000))
+}
+
+func BenchmarkLRFUZipfian(b *testing.B) {
+	benchmarkPolicyZipfian(b, NewLRFU(1000, 0.01))
+}
+
+func TestSieveSetGetRemove(t *testing.T) {
+	s := NewSieve(8)
+
+	s.Set("a", 1)
+	if v, ok := s.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, ok)
+	}
+
+	if !s.Remove("a") {
+		t.Fatal("Remove(a) = false; want true")
+	}
+	if _, ok := s.Get("a"); ok {
+		t.Fatal("Get(a) after Remove = true; want false")
+	}
+}
+
+// TestSieveVisitedEntriesSurviveOneSweep checks the defining SIEVE
+// behavior: a visited entry is spared on the hand's first pass (its
+// visited bit is cleared instead), so it survives at least one eviction
+// that an unvisited entry would not.
+func TestSieveVisitedEntriesSurviveOneSweep(t *testing.T) {
+	s := NewSieve(3)
+	var evicted []Key
+	s.SetOnEvicted(func(k Key, v interface{}) { evicted = append(evicted, k) })
+
+	s.Set("a", 1)
+	s.Set("b", 2)
+	s.Set("c", 3)
+
+	s.Get("a") // mark "a" visited so the hand should skip over it
+
+	s.Set("d", 4) // forces one eviction
+
+	for _, k := range evicted {
+		if k == "a" {
+			t.Fatal("expected visited entry \"a\" to survive the first sweep")
+		}
+	}
+}
+
+func TestSieveClear(t *testing.T) {
+	s := NewSieve(8)
+	s.Set("a", 1)
+	s.Set("b", 2)
+
+	s.Clear()
+
+	if s.Len() != 0 {
+		t.Fatalf("Len() after Clear = %d, want 0", s.Len())
+	}
+}
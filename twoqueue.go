@@ -0,0 +1,227 @@
+package lrfu
+
+import "container/list"
+
+const (
+	// default2QRecentRatio is the default fraction of maxEntries reserved
+	// for the recent (A1in) FIFO queue.
+	default2QRecentRatio = 0.25
+	// default2QGhostRatio is the default fraction of maxEntries used to
+	// size the ghost list (A1out) of recently evicted recent-queue keys.
+	default2QGhostRatio = 0.50
+)
+
+type twoQueueEntry struct {
+	key   Key
+	value interface{}
+}
+
+// TwoQueue implements the 2Q replacement policy: entries seen once sit in
+// a FIFO queue (recent), promoting to an LRU list (frequent) on a second
+// access, with a ghost list of recently evicted recent-queue keys used to
+// tell a scan apart from genuine reuse.
+type TwoQueue struct {
+	maxEntries int
+	recentSize int
+	ghostSize  int
+	OnEvicted  func(key Key, value interface{})
+
+	recent      *list.List
+	recentGhost *list.List
+	frequent    *list.List
+
+	recentMap      map[interface{}]*list.Element
+	recentGhostMap map[interface{}]*list.Element
+	frequentMap    map[interface{}]*list.Element
+}
+
+// NewTwoQueue creates a TwoQueue with the default recent/ghost ratios.
+func NewTwoQueue(maxEntries int) *TwoQueue {
+	return NewTwoQueueParams(maxEntries, default2QRecentRatio, default2QGhostRatio)
+}
+
+// NewTwoQueueParams creates a TwoQueue with custom recent/ghost ratios,
+// each expressed as a fraction of maxEntries.
+func NewTwoQueueParams(maxEntries int, recentRatio, ghostRatio float64) *TwoQueue {
+	return &TwoQueue{
+		maxEntries:     maxEntries,
+		recentSize:     int(float64(maxEntries) * recentRatio),
+		ghostSize:      int(float64(maxEntries) * ghostRatio),
+		recent:         list.New(),
+		recentGhost:    list.New(),
+		frequent:       list.New(),
+		recentMap:      make(map[interface{}]*list.Element),
+		recentGhostMap: make(map[interface{}]*list.Element),
+		frequentMap:    make(map[interface{}]*list.Element),
+	}
+}
+
+// Set inserts or updates key with value.
+func (q *TwoQueue) Set(key Key, value interface{}) {
+	if ele, hit := q.frequentMap[key]; hit {
+		q.frequent.MoveToFront(ele)
+		ele.Value.(*twoQueueEntry).value = value
+		return
+	}
+
+	if ele, hit := q.recentMap[key]; hit {
+		ele.Value.(*twoQueueEntry).value = value
+		return
+	}
+
+	if ele, hit := q.recentGhostMap[key]; hit {
+		q.ensureSpace(true)
+		q.recentGhost.Remove(ele)
+		delete(q.recentGhostMap, key)
+
+		fe := q.frequent.PushFront(&twoQueueEntry{key: key, value: value})
+		q.frequentMap[key] = fe
+		return
+	}
+
+	q.ensureSpace(false)
+	re := q.recent.PushFront(&twoQueueEntry{key: key, value: value})
+	q.recentMap[key] = re
+}
+
+// Get looks up key, promoting it from recent to frequent on a second
+// access.
+func (q *TwoQueue) Get(key Key) (value interface{}, ok bool) {
+	if ele, hit := q.frequentMap[key]; hit {
+		q.frequent.MoveToFront(ele)
+		return ele.Value.(*twoQueueEntry).value, true
+	}
+
+	if ele, hit := q.recentMap[key]; hit {
+		kv := ele.Value.(*twoQueueEntry)
+		q.recent.Remove(ele)
+		delete(q.recentMap, key)
+
+		fe := q.frequent.PushFront(kv)
+		q.frequentMap[key] = fe
+		return kv.value, true
+	}
+
+	return
+}
+
+// Peek returns the value associated with key without moving it between the
+// recent and frequent queues.
+func (q *TwoQueue) Peek(key Key) (value interface{}, ok bool) {
+	if ele, hit := q.frequentMap[key]; hit {
+		return ele.Value.(*twoQueueEntry).value, true
+	}
+	if ele, hit := q.recentMap[key]; hit {
+		return ele.Value.(*twoQueueEntry).value, true
+	}
+	return
+}
+
+// Remove removes key from the recent or frequent queue, or its ghost
+// entry, if present in any of them.
+func (q *TwoQueue) Remove(key Key) (ok bool) {
+	if ele, hit := q.frequentMap[key]; hit {
+		q.frequent.Remove(ele)
+		delete(q.frequentMap, key)
+		return true
+	}
+	if ele, hit := q.recentMap[key]; hit {
+		q.recent.Remove(ele)
+		delete(q.recentMap, key)
+		return true
+	}
+	if ele, hit := q.recentGhostMap[key]; hit {
+		q.recentGhost.Remove(ele)
+		delete(q.recentGhostMap, key)
+		return true
+	}
+	return false
+}
+
+// Len returns the number of live (non-ghost) entries.
+func (q *TwoQueue) Len() int {
+	return q.recent.Len() + q.frequent.Len()
+}
+
+// Clear removes all entries, live and ghost.
+func (q *TwoQueue) Clear() {
+	if q.OnEvicted != nil {
+		for _, e := range q.recentMap {
+			kv := e.Value.(*twoQueueEntry)
+			q.OnEvicted(kv.key, kv.value)
+		}
+		for _, e := range q.frequentMap {
+			kv := e.Value.(*twoQueueEntry)
+			q.OnEvicted(kv.key, kv.value)
+		}
+	}
+
+	q.recent = list.New()
+	q.recentGhost = list.New()
+	q.frequent = list.New()
+	q.recentMap = make(map[interface{}]*list.Element)
+	q.recentGhostMap = make(map[interface{}]*list.Element)
+	q.frequentMap = make(map[interface{}]*list.Element)
+}
+
+// SetOnEvicted sets OnEvicted, the callback fired whenever an entry is
+// evicted from the recent or frequent queue.
+func (q *TwoQueue) SetOnEvicted(fn func(key Key, value interface{})) {
+	q.OnEvicted = fn
+}
+
+// ensureSpace evicts from recent or frequent to make room for a new entry,
+// demoting evicted recent entries into the ghost list.
+func (q *TwoQueue) ensureSpace(recentPromoting bool) {
+	if q.recent.Len()+q.frequent.Len() < q.maxEntries {
+		return
+	}
+
+	if q.recent.Len() > 0 && (q.recent.Len() > q.recentSize || recentPromoting) {
+		q.evictRecentToGhost()
+		return
+	}
+
+	q.evictFrequent()
+}
+
+func (q *TwoQueue) evictRecentToGhost() {
+	ele := q.recent.Back()
+	if ele == nil {
+		return
+	}
+
+	kv := ele.Value.(*twoQueueEntry)
+	q.recent.Remove(ele)
+	delete(q.recentMap, kv.key)
+
+	if q.OnEvicted != nil {
+		q.OnEvicted(kv.key, kv.value)
+	}
+
+	if q.recentGhost.Len() >= q.ghostSize {
+		oldest := q.recentGhost.Back()
+		if oldest != nil {
+			q.recentGhost.Remove(oldest)
+			delete(q.recentGhostMap, oldest.Value.(*twoQueueEntry).key)
+		}
+	}
+
+	ge := q.recentGhost.PushFront(&twoQueueEntry{key: kv.key})
+	q.recentGhostMap[kv.key] = ge
+}
+
+func (q *TwoQueue) evictFrequent() {
+	ele := q.frequent.Back()
+	if ele == nil {
+		return
+	}
+
+	kv := ele.Value.(*twoQueueEntry)
+	q.frequent.Remove(ele)
+	delete(q.frequentMap, kv.key)
+
+	if q.OnEvicted != nil {
+		q.OnEvicted(kv.key, kv.value)
+	}
+}
@@ -0,0 +1,67 @@
+package lrfu
+
+import "testing"
+
+func TestTwoQueueSetGetRemove(t *testing.T) {
+	q := NewTwoQueue(8)
+
+	q.Set("a", 1)
+	if v, ok := q.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, ok)
+	}
+
+	if !q.Remove("a") {
+		t.Fatal("Remove(a) = false; want true")
+	}
+	if _, ok := q.Get("a"); ok {
+		t.Fatal("Get(a) after Remove = true; want false")
+	}
+}
+
+func TestTwoQueuePromotesOnSecondAccess(t *testing.T) {
+	q := NewTwoQueue(8)
+	q.Set("a", 1)
+
+	if q.recent.Len() != 1 || q.frequent.Len() != 0 {
+		t.Fatalf("after Set: recent=%d frequent=%d, want recent=1 frequent=0", q.recent.Len(), q.frequent.Len())
+	}
+
+	q.Get("a")
+
+	if q.recent.Len() != 0 || q.frequent.Len() != 1 {
+		t.Fatalf("after Get: recent=%d frequent=%d, want recent=0 frequent=1", q.recent.Len(), q.frequent.Len())
+	}
+}
+
+func TestTwoQueueGhostPromotesStraightToFrequent(t *testing.T) {
+	q := NewTwoQueueParams(2, 0.5, 0.5)
+	var evicted []Key
+	q.SetOnEvicted(func(k Key, v interface{}) { evicted = append(evicted, k) })
+
+	q.Set("a", 1)
+	q.Set("b", 2)
+	q.Set("c", 3) // evicts "a" from recent into the recent ghost list
+
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Fatalf("evicted = %v, want [a]", evicted)
+	}
+
+	q.Set("a", 10) // hits the ghost list, so "a" should land directly in frequent
+
+	if _, hit := q.frequentMap["a"]; !hit {
+		t.Fatal("expected \"a\" to be promoted straight to frequent on a ghost hit")
+	}
+}
+
+func TestTwoQueueClear(t *testing.T) {
+	q := NewTwoQueue(8)
+	q.Set("a", 1)
+	q.Get("a")
+	q.Set("b", 2)
+
+	q.Clear()
+
+	if q.Len() != 0 {
+		t.Fatalf("Len() after Clear = %d, want 0", q.Len())
+	}
+}